@@ -0,0 +1,587 @@
+// Copyright 2011 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package chain implements the Markov chain algorithm used to generate
+random text.
+
+Based on the program presented in the "Design and Implementation" chapter
+of The Practice of Programming (Kernighan and Pike, Addison-Wesley 1999).
+See also Computer Recreations, Scientific American 260, 122 - 125 (1989).
+
+A Markov chain algorithm generates text by creating a statistical model of
+potential textual suffixes for a given prefix. Consider this text:
+
+	I am not a number! I am a free man!
+
+Our Markov chain algorithm would arrange this text into this set of prefixes
+and suffixes, or "chain": (This table assumes a prefix length of two words
+and DefaultTokenizer, which splits "!" off as a token of its own.)
+
+	Prefix       Suffix
+
+	"" ""        I
+	"" I         am
+	! I          am
+	I am         a
+	I am         not
+	a free       man
+	a number     !
+	am a         free
+	am not       a
+	free man     !
+	not a        number
+	number !     I
+
+To generate text using this table we select an initial prefix ("I am", for
+example), choose one of the suffixes associated with that prefix at random
+with probability determined by the input statistics ("a"),
+and then create a new prefix by removing the first word from the prefix
+and appending the suffix (making the new prefix is "am a"). Repeat this process
+until we can't find any suffixes for the current prefix or we exceed the word
+limit. (The word limit is necessary as the chain table may contain cycles.)
+*/
+package chain
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Prefix is a Markov chain prefix of one or more words.
+type Prefix []string
+
+// String returns the Prefix as a string (for use as a map key).
+func (p Prefix) String() string {
+	return strings.Join(p, " ")
+}
+
+// Shift removes the first word from the Prefix and appends the given word.
+func (p Prefix) Shift(word string) {
+	copy(p, p[1:])
+	p[len(p)-1] = word
+}
+
+// Chain holds a frequency table ("freqTable") of prefixes to suffix
+// counts. A prefix is a string of prefixLen words joined with spaces.
+// A suffix is a single word. A prefix can have multiple suffixes, each
+// with an observed count. mu guards freqTable so a Chain can be trained
+// from several goroutines at once and read (via Generate) while
+// training is in progress. Chain deliberately has no prefix cursor of
+// its own: that state belongs to whoever is training one document, via
+// ChainWriter.
+type Chain struct {
+	mu        sync.RWMutex
+	prefixLen int
+	freqTable map[string]map[string]int
+
+	// Tokenizer splits newly written text into words, punctuation and
+	// sentence-boundary tokens. It defaults to DefaultTokenizer but can
+	// be replaced with an alternative splitting scheme.
+	Tokenizer Tokenizer
+}
+
+// NewChain returns a new Chain with prefixes of prefixLen words.
+func NewChain(prefixLen int) *Chain {
+	return &Chain{
+		prefixLen: prefixLen,
+		freqTable: make(map[string]map[string]int),
+		Tokenizer: DefaultTokenizer,
+	}
+}
+
+// ChainWriter trains a Chain on a single document. It implements
+// io.Writer so a document can be streamed in across many Write calls —
+// from a file, a socket, or an HTTP request body — without buffering
+// the whole input up front. A ChainWriter owns its own prefix cursor and
+// partial-token buffer, so several ChainWriters can train the same
+// Chain concurrently (from several goroutines, or several in-flight
+// requests) without their documents' words getting linked to each
+// other; each Write only takes Chain's lock for the brief critical
+// section that updates freqTable.
+type ChainWriter struct {
+	c       *Chain
+	prefix  Prefix
+	partial []byte
+}
+
+// Writer returns a new ChainWriter that trains c on one document,
+// starting from the empty-sentinel prefix. Give every independent
+// document its own ChainWriter.
+func (c *Chain) Writer() *ChainWriter {
+	return &ChainWriter{c: c, prefix: emptyPrefix(c.prefixLen)}
+}
+
+// Write implements io.Writer, feeding p into w's document.
+func (w *ChainWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	tokens, trailing := w.c.Tokenizer(string(w.partial))
+	w.partial = []byte(trailing)
+
+	w.c.mu.Lock()
+	defer w.c.mu.Unlock()
+	for _, s := range tokens {
+		w.c.addWord(w.prefix, s)
+	}
+	return len(p), nil
+}
+
+// addWord records s as the suffix of prefix and advances prefix.
+// Callers must hold c.mu.
+func (c *Chain) addWord(prefix Prefix, s string) {
+	key := prefix.String()
+
+	if val, ok := c.freqTable[key]; ok { // if prefix is in table
+		val[s]++
+	} else { // if prefix is not in table
+		c.freqTable[key] = map[string]int{s: 1}
+	}
+
+	prefix.Shift(s)
+}
+
+// Tokenizer splits s into tokens, returning any trailing run of runes
+// that might still be incomplete (e.g. a word cut off mid-write) so the
+// caller can prepend it to the next chunk of text before tokenizing
+// again.
+type Tokenizer func(s string) (tokens []string, trailing string)
+
+// DefaultTokenizer is the Chain.Tokenizer installed by NewChain. It
+// splits on whitespace, treats runs of letters and digits as word
+// tokens, and emits every other rune (punctuation, symbols, ...) as its
+// own single-rune token — so sentence-ending punctuation ("." "!" "?")
+// becomes its own suffix rather than being glued onto the preceding
+// word or dropped.
+func DefaultTokenizer(s string) (tokens []string, trailing string) {
+	complete, incomplete := splitTrailingIncompleteRune(s)
+
+	var word []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = nil
+		}
+	}
+
+	for _, r := range complete {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			flushWord()
+			tokens = append(tokens, string(r))
+		}
+	}
+
+	if len(word) > 0 {
+		trailing = string(word)
+	}
+	return tokens, trailing + incomplete
+}
+
+// splitTrailingIncompleteRune splits off the bytes of a multi-byte rune
+// left incomplete at the end of s, e.g. by a Write call that landed
+// mid-rune (a real possibility when streaming from a socket or an HTTP
+// body, which can split anywhere). Without this, ranging over s would
+// decode those dangling bytes as utf8.RuneError and tokenize them as
+// garbage instead of holding them back for the next Write to complete.
+// A genuinely malformed (not just incomplete) sequence is left in
+// complete, since it will never become valid no matter what follows.
+func splitTrailingIncompleteRune(s string) (complete, incomplete string) {
+	if len(s) == 0 {
+		return s, ""
+	}
+
+	i := len(s) - 1
+	for i > 0 && i > len(s)-utf8.UTFMax && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	if !utf8.FullRuneInString(s[i:]) {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+// PrintFreqTable prints a frequency table
+func (c *Chain) PrintFreqTable() {
+	for k, v := range c.freqTable {
+		var sufCount []string
+		for k2, v2 := range v {
+			sufCount = append(sufCount, k2, strconv.Itoa(v2))
+		}
+		fmt.Println(k + " " + strings.Join(sufCount, " "))
+	}
+}
+
+//FreqTableToFile writes the contents of a chain to a file
+func (c *Chain) FreqTableToFile(filepath string) {
+	// Open file
+	openFile, err := os.Create(filepath)
+	if err != nil {
+		panic("Could not create file from given file path")
+	}
+	defer openFile.Close()
+
+	writer := bufio.NewWriter(openFile)
+	fmt.Fprintln(writer, strconv.Itoa(c.prefixLen))
+
+	// Sort frequence table
+	var keys []string
+	for k := range c.freqTable {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Write each line of the output file
+	for _, key := range keys {
+		var sufCount []string
+		for k2, v2 := range c.freqTable[key] {
+			sufCount = append(sufCount, k2, strconv.Itoa(v2))
+		}
+		fmt.Fprintln(writer, key+" "+strings.Join(sufCount, " "))
+	}
+	writer.Flush()
+}
+
+// gobMagic is written at the start of a gob-encoded Chain so that
+// readers can tell the binary format apart from the text format without
+// being told which one to expect.
+const gobMagic = "MVSGOB1\n"
+
+// chainGob is the on-the-wire representation of a Chain for
+// encoding/gob, since Chain's own fields are unexported and it embeds a
+// mutex that must never be serialized.
+type chainGob struct {
+	PrefixLen int
+	FreqTable map[string]map[string]int
+}
+
+// Save writes the Chain's prefix length and frequency table to w as a
+// gob stream prefixed with gobMagic. Unlike the text format produced by
+// FreqTableToFile, it round-trips suffixes containing spaces or other
+// punctuation exactly, and is both smaller and faster to load for large
+// corpora since counts aren't re-formatted through strconv.
+func (c *Chain) Save(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, err := io.WriteString(w, gobMagic); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(chainGob{
+		PrefixLen: c.prefixLen,
+		FreqTable: c.freqTable,
+	})
+}
+
+// decodeGob reads and validates the gobMagic prefix from r, then
+// decodes the chainGob payload that follows it.
+func decodeGob(r io.Reader) (chainGob, error) {
+	magic := make([]byte, len(gobMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return chainGob{}, fmt.Errorf("could not read gob magic: %w", err)
+	}
+	if string(magic) != gobMagic {
+		return chainGob{}, errors.New("not a gob-encoded chain")
+	}
+
+	var g chainGob
+	if err := gob.NewDecoder(r).Decode(&g); err != nil {
+		return chainGob{}, err
+	}
+	return g, nil
+}
+
+// LoadChain reads a Chain previously written by Save.
+func LoadChain(r io.Reader) (*Chain, error) {
+	g, err := decodeGob(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewChain(g.PrefixLen)
+	c.freqTable = g.FreqTable
+	return c, nil
+}
+
+// Load replaces c's state in place with a Chain previously written by
+// Save and read from r, so a long-lived Chain (for example one a
+// running server is already serving Generate from) can be restored from
+// a snapshot without callers needing a new pointer.
+func (c *Chain) Load(r io.Reader) error {
+	g, err := decodeGob(r)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prefixLen = g.PrefixLen
+	c.freqTable = g.FreqTable
+	return nil
+}
+
+// FreqTableFromFreqFile create a frequency table from an freqTable file
+func FreqTableFromFreqFile(freqFile string) *Chain {
+	openFile, err := os.Open(freqFile)
+	if err != nil {
+		panic("Could not open frequency table file.")
+	}
+	defer openFile.Close()
+	return FreqTableFromReader(openFile)
+}
+
+// FreqTableFromReader parses the text frequency-table format from r.
+// It is the shared implementation behind FreqTableFromFreqFile and
+// format auto-detection in LoadFormat.
+func FreqTableFromReader(r io.Reader) *Chain {
+	scanner := bufio.NewScanner(r)
+
+	c := NewChain(0)
+	for scanner.Scan() {
+		items := strings.Split(scanner.Text(), " ")
+		if len(items) > 1 { // if not first line of file and line is not empty
+			// set prefix for the line
+			var p Prefix
+			for i := 0; i < c.prefixLen; i++ {
+				p = append(p, items[i])
+			}
+			key := p.String()
+			c.freqTable[key] = make(map[string]int)
+
+			// update prefix map with suffix frequencies
+			for i := c.prefixLen; i < len(items); i = i + 2 {
+				sfxFreq, err := strconv.Atoi(items[i+1])
+				if err != nil {
+					panic("Could not convert string to integer")
+				}
+				c.freqTable[key][items[i]] = sfxFreq
+			}
+		} else if len(items) == 1 { // if first line or empty line
+			if len(items[0]) > 0 { // if ifrst line
+				// Set the prefix length for the chain
+				prefixLen, err := strconv.Atoi(items[0])
+				if err != nil {
+					panic("Could not convert string to integer")
+				}
+				c.prefixLen = prefixLen
+			}
+		}
+	}
+	return c
+}
+
+// DetectFormat peeks at br's leading bytes to tell a gob-encoded Chain
+// (written by Save) apart from the text frequency-table format.
+func DetectFormat(br *bufio.Reader) string {
+	magic, err := br.Peek(len(gobMagic))
+	if err == nil && string(magic) == gobMagic {
+		return "gob"
+	}
+	return "text"
+}
+
+// LoadFormat parses a Chain from r using format ("text" or "gob"), or
+// by auto-detecting the format from r's leading bytes when format is
+// "".
+func LoadFormat(r io.Reader, format string) (*Chain, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	if format == "" {
+		format = DetectFormat(br)
+	}
+
+	switch format {
+	case "gob":
+		return LoadChain(br)
+	case "text":
+		return FreqTableFromReader(br), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// Generate returns a string of at most n words generated from Chain.
+// It takes a read lock so generation is safe to run concurrently with
+// training via ChainWriter. Suffixes are chosen with probability
+// proportional to their observed counts in freqTable.
+func (c *Chain) Generate(n int) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generate(n, emptyPrefix(c.prefixLen), rand.Intn)
+}
+
+// GenerateWithSeed returns a string of at most n words generated from
+// Chain using a private *rand.Rand seeded with seed, so the output is
+// reproducible across runs.
+func (c *Chain) GenerateWithSeed(n int, seed int64) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	rnd := rand.New(rand.NewSource(seed))
+	return c.generate(n, emptyPrefix(c.prefixLen), rnd.Intn)
+}
+
+// GenerateFrom returns a string of at most n words generated from
+// Chain, starting from a prefix built out of seedWords instead of the
+// empty-sentinel prefix, so callers can steer generation toward a
+// topic — mirroring a chat bot replying "about" the last thing it
+// heard. If the exact seed prefix isn't in the table, GenerateFrom
+// drops the leftmost seed word and tries again, falling back to the
+// empty-sentinel prefix once the seed is exhausted.
+func (c *Chain) GenerateFrom(seedWords []string, n int) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generate(n, c.seedPrefix(seedWords), rand.Intn)
+}
+
+// seedPrefix finds the longest tail of seedWords with a matching entry
+// in freqTable, falling back to the empty-sentinel prefix. Callers must
+// hold c.mu for reading.
+func (c *Chain) seedPrefix(seedWords []string) Prefix {
+	remaining := seedWords
+	for {
+		p := buildPrefix(remaining, c.prefixLen)
+		if len(remaining) == 0 || len(c.freqTable[p.String()]) > 0 {
+			return p
+		}
+		remaining = remaining[1:]
+	}
+}
+
+// emptyPrefix returns the empty-sentinel prefix Generate starts from.
+func emptyPrefix(prefixLen int) Prefix {
+	return buildPrefix(nil, prefixLen)
+}
+
+// buildPrefix builds a Prefix of exactly prefixLen words from the tail
+// of words, padding any missing leading words with the empty-sentinel
+// token.
+func buildPrefix(words []string, prefixLen int) Prefix {
+	p := make(Prefix, prefixLen)
+	for i := range p {
+		p[i] = "\"\""
+	}
+	n := len(words)
+	if n > prefixLen {
+		words = words[n-prefixLen:]
+		n = prefixLen
+	}
+	copy(p[prefixLen-n:], words)
+	return p
+}
+
+// generate is the shared implementation behind Generate, GenerateWithSeed
+// and GenerateFrom. It starts from startPrefix and calls intn(total) to
+// pick a value in [0, total) when choosing each suffix; callers must
+// hold c.mu for reading.
+func (c *Chain) generate(n int, startPrefix Prefix, intn func(int) int) string {
+	p := make(Prefix, len(startPrefix))
+	copy(p, startPrefix)
+
+	var words []string
+	for i := 0; i < n; i++ {
+		next, ok := c.weightedSuffix(p.String(), intn)
+		if !ok {
+			break
+		}
+		words = append(words, next)
+		p.Shift(next)
+	}
+	return strings.Join(words, " ")
+}
+
+// GenerateSentence returns text generated from Chain that stops at the
+// first sentence-boundary token (".", "!" or "?") rather than running
+// on for a fixed word count — useful for a "headline" that should read
+// as one grammatically-plausible sentence. maxWords bounds the output
+// in case the chain never reaches a boundary.
+func (c *Chain) GenerateSentence(maxWords int) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generateSentence(maxWords, emptyPrefix(c.prefixLen), rand.Intn)
+}
+
+// GenerateSentenceFrom is GenerateSentence starting from a prefix built
+// out of seedWords instead of the empty-sentinel prefix, the same
+// seed-prefix fallback GenerateFrom uses.
+func (c *Chain) GenerateSentenceFrom(seedWords []string, maxWords int) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generateSentence(maxWords, c.seedPrefix(seedWords), rand.Intn)
+}
+
+func (c *Chain) generateSentence(maxWords int, startPrefix Prefix, intn func(int) int) string {
+	p := make(Prefix, len(startPrefix))
+	copy(p, startPrefix)
+
+	var words []string
+	for i := 0; i < maxWords; i++ {
+		next, ok := c.weightedSuffix(p.String(), intn)
+		if !ok {
+			break
+		}
+		words = append(words, next)
+		p.Shift(next)
+		if isSentenceBoundary(next) {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// isSentenceBoundary reports whether token ends a sentence.
+func isSentenceBoundary(token string) bool {
+	return token == "." || token == "!" || token == "?"
+}
+
+// weightedSuffix picks a suffix for key from freqTable with probability
+// proportional to its observed count. It builds a sorted list of
+// suffixes and a parallel slice of cumulative counts, then selects a
+// random point in [0, total) with intn and binary-searches for it with
+// sort.SearchInts, rather than expanding counts into a slice of
+// duplicates.
+func (c *Chain) weightedSuffix(key string, intn func(int) int) (string, bool) {
+	counts := c.freqTable[key]
+	if len(counts) == 0 {
+		return "", false
+	}
+
+	suffixes := make([]string, 0, len(counts))
+	for s := range counts {
+		suffixes = append(suffixes, s)
+	}
+	sort.Strings(suffixes)
+
+	cumulative := make([]int, len(suffixes))
+	total := 0
+	for i, s := range suffixes {
+		total += counts[s]
+		cumulative[i] = total
+	}
+
+	target := intn(total)
+	i := sort.SearchInts(cumulative, target+1)
+	return suffixes[i], true
+}
@@ -0,0 +1,82 @@
+package chain
+
+import "testing"
+
+func TestGenerateWithSeedIsDeterministic(t *testing.T) {
+	c := NewChain(1)
+	mustWrite(t, c.Writer(), "the cat sat on the mat the cat ran the dog sat ")
+
+	first := c.GenerateWithSeed(20, 42)
+	second := c.GenerateWithSeed(20, 42)
+	if first != second {
+		t.Fatalf("GenerateWithSeed(20, 42) returned %q then %q, want identical output for the same seed", first, second)
+	}
+}
+
+// TestWeightedSuffixRespectsCounts checks that weightedSuffix only ever
+// returns suffixes recorded for the given prefix, and that a suffix
+// with many more observed occurrences is picked noticeably more often
+// than a rare one.
+func TestWeightedSuffixRespectsCounts(t *testing.T) {
+	c := NewChain(1)
+	c.freqTable["a"] = map[string]int{"common": 95, "rare": 5}
+
+	counts := map[string]int{}
+	intn := 0
+	const trials = 100
+	for i := 0; i < trials; i++ {
+		// Walk intn deterministically across [0, 100) instead of using
+		// real randomness, so the test doesn't flake.
+		s, ok := c.weightedSuffix("a", func(total int) int { return intn % total })
+		if !ok {
+			t.Fatalf("weightedSuffix(%q) returned ok=false, want a suffix", "a")
+		}
+		counts[s]++
+		intn++
+	}
+
+	if counts["common"] <= counts["rare"] {
+		t.Fatalf("counts = %v, want \"common\" picked more often than \"rare\"", counts)
+	}
+	if counts["common"]+counts["rare"] != trials {
+		t.Fatalf("counts = %v, want every trial to land on \"common\" or \"rare\"", counts)
+	}
+}
+
+func TestWeightedSuffixNoEntriesForPrefix(t *testing.T) {
+	c := NewChain(1)
+	if _, ok := c.weightedSuffix("missing", func(int) int { return 0 }); ok {
+		t.Fatalf("weightedSuffix(%q) returned ok=true for a prefix with no suffixes", "missing")
+	}
+}
+
+// TestSeedPrefixFallback trains a prefixLen=2 chain on "alpha beta
+// gamma " and walks seedPrefix through its three possible outcomes:
+// an exact match on the full seed, a match found only after dropping
+// the leftmost seed word, and a full fallback to the empty-sentinel
+// prefix when nothing in the seed matches anything.
+func TestSeedPrefixFallback(t *testing.T) {
+	c := NewChain(2)
+	mustWrite(t, c.Writer(), "alpha beta gamma ")
+
+	tests := []struct {
+		name      string
+		seedWords []string
+		want      string
+	}{
+		{"exact match", []string{"alpha", "beta"}, "alpha beta"},
+		{"match after dropping leftmost word", []string{"nomatch", "alpha"}, `"" alpha`},
+		{"falls back to empty-sentinel prefix", []string{"nomatch1", "nomatch2"}, `"" ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.seedPrefix(tt.seedWords).String(); got != tt.want {
+				t.Fatalf("seedPrefix(%v) = %q, want %q", tt.seedWords, got, tt.want)
+			}
+			if got := c.GenerateFrom(tt.seedWords, 10); got == "" {
+				t.Fatalf("GenerateFrom(%v, 10) = %q, want non-empty output", tt.seedWords, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package chain
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSaveAndLoadChainRoundTrip(t *testing.T) {
+	c := NewChain(2)
+	mustWrite(t, c.Writer(), "the quick brown fox the quick red fox ")
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	loaded, err := LoadChain(&buf)
+	if err != nil {
+		t.Fatalf("LoadChain returned error: %v", err)
+	}
+
+	if loaded.prefixLen != c.prefixLen {
+		t.Fatalf("loaded.prefixLen = %d, want %d", loaded.prefixLen, c.prefixLen)
+	}
+	for prefix, suffixes := range c.freqTable {
+		for suffix, count := range suffixes {
+			if got := loaded.freqTable[prefix][suffix]; got != count {
+				t.Fatalf("loaded.freqTable[%q][%q] = %d, want %d", prefix, suffix, got, count)
+			}
+		}
+	}
+}
+
+func TestLoadChainRejectsNonGobData(t *testing.T) {
+	if _, err := LoadChain(bytes.NewReader([]byte("1\n\"\" the 1\n"))); err == nil {
+		t.Fatalf("LoadChain on text-format data returned nil error, want an error")
+	}
+}
+
+func TestChainLoadRestoresInPlace(t *testing.T) {
+	c := NewChain(2)
+	mustWrite(t, c.Writer(), "alpha beta gamma ")
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	restoreInto := NewChain(2)
+	mustWrite(t, restoreInto.Writer(), "totally different text ")
+	if err := restoreInto.Load(&buf); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if _, ok := restoreInto.freqTable["alpha beta"]; !ok {
+		t.Fatalf("restoreInto.freqTable missing %q after Load, got %v", "alpha beta", restoreInto.freqTable)
+	}
+	if _, ok := restoreInto.freqTable["totally different"]; ok {
+		t.Fatalf("restoreInto.freqTable still has pre-Load entry %q, want it replaced", "totally different")
+	}
+}
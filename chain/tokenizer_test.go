@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTokenizerSplitsPunctuationAndSentences(t *testing.T) {
+	tokens, trailing := DefaultTokenizer(`I am not a number! I am a free man!`)
+	want := []string{"I", "am", "not", "a", "number", "!", "I", "am", "a", "free", "man", "!"}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Fatalf("tokens = %v, want %v", tokens, want)
+	}
+	if trailing != "" {
+		t.Fatalf("trailing = %q, want empty (input ended on a token boundary)", trailing)
+	}
+}
+
+func TestDefaultTokenizerHoldsBackIncompleteWord(t *testing.T) {
+	tokens, trailing := DefaultTokenizer("hello wor")
+	if want := []string{"hello"}; !reflect.DeepEqual(tokens, want) {
+		t.Fatalf("tokens = %v, want %v", tokens, want)
+	}
+	if trailing != "wor" {
+		t.Fatalf("trailing = %q, want %q", trailing, "wor")
+	}
+}
+
+func TestDefaultTokenizerUnicodeLetters(t *testing.T) {
+	tokens, trailing := DefaultTokenizer("café naïve ")
+	want := []string{"café", "naïve"}
+	if !reflect.DeepEqual(tokens, want) {
+		t.Fatalf("tokens = %v, want %v", tokens, want)
+	}
+	if trailing != "" {
+		t.Fatalf("trailing = %q, want empty", trailing)
+	}
+}
+
+func TestWriteHoldsBackRuneSplitAcrossWrites(t *testing.T) {
+	c := NewChain(1)
+	w := c.Writer()
+
+	// "café " with the two-byte "é" (0xC3 0xA9) split across calls, the
+	// way a socket read or a chunked HTTP body could split it.
+	mustWrite(t, w, "caf\xc3")
+	mustWrite(t, w, "\xa9 ")
+
+	if got := c.freqTable[`""`]["café"]; got != 1 {
+		t.Fatalf(`freqTable["\"\""]["café"] = %d, want 1 (got freqTable = %v)`, got, c.freqTable)
+	}
+	for prefix, suffixes := range c.freqTable {
+		for suffix := range suffixes {
+			if strings.ContainsRune(prefix, '�') || strings.ContainsRune(suffix, '�') {
+				t.Fatalf("freqTable contains a replacement character, want the split rune reassembled: %v", c.freqTable)
+			}
+		}
+	}
+}
+
+func TestGenerateSentenceStopsAtBoundary(t *testing.T) {
+	c := NewChain(1)
+	mustWrite(t, c.Writer(), "the dog barks ! the dog runs . ")
+
+	got := c.GenerateSentence(50)
+	fields := strings.Fields(got)
+	if len(fields) == 0 || !isSentenceBoundary(fields[len(fields)-1]) {
+		t.Fatalf("GenerateSentence(50) = %q, want it to end on a sentence boundary", got)
+	}
+}
+
+func TestGenerateSentenceFromUsesSeedPrefix(t *testing.T) {
+	c := NewChain(1)
+	mustWrite(t, c.Writer(), "the dog barks ! ")
+
+	got := c.GenerateSentenceFrom([]string{"the"}, 50)
+	if got == "" {
+		t.Fatalf("GenerateSentenceFrom([\"the\"], 50) = %q, want non-empty output", got)
+	}
+}
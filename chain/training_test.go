@@ -0,0 +1,85 @@
+package chain
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestChainWriterIsolatesDocuments reproduces the cross-document
+// corruption bug fixed for chunk0-1: two ChainWriters training the same
+// Chain from interleaved chunks must never link a suffix from one
+// document to a prefix from the other.
+func TestChainWriterIsolatesDocuments(t *testing.T) {
+	c := NewChain(1)
+
+	a := c.Writer()
+	b := c.Writer()
+
+	// Interleave two documents' chunks through independent ChainWriters,
+	// the way concurrent goroutines or requests would.
+	mustWrite(t, a, "alpha beta ")
+	mustWrite(t, b, "zeta eta ")
+	mustWrite(t, a, "gamma delta ")
+	mustWrite(t, b, "theta iota ")
+
+	if _, ok := c.freqTable["eta"]["gamma"]; ok {
+		t.Fatalf(`freqTable["eta"] contains "gamma": a suffix from document A leaked into document B's prefix`)
+	}
+	if got := c.freqTable["alpha"]; got["beta"] != 1 {
+		t.Fatalf(`freqTable["alpha"] = %v, want {"beta": 1}`, got)
+	}
+	if got := c.freqTable["zeta"]; got["eta"] != 1 {
+		t.Fatalf(`freqTable["zeta"] = %v, want {"eta": 1}`, got)
+	}
+}
+
+// TestChainWriterConcurrent trains several documents into one Chain
+// from concurrent goroutines and checks that no suffix from one
+// document's tail was recorded against another's head. Run with
+// -race to catch data races on freqTable.
+func TestChainWriterConcurrent(t *testing.T) {
+	c := NewChain(1)
+	docs := []string{
+		"one two three four five",
+		"six seven eight nine ten",
+		"red green blue yellow purple",
+	}
+
+	var wg sync.WaitGroup
+	for _, doc := range docs {
+		doc := doc
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mustWrite(t, c.Writer(), doc+" ")
+		}()
+	}
+	wg.Wait()
+
+	tails := map[string]string{"five": "six", "ten": "red", "purple": "one"}
+	for tail, head := range tails {
+		if _, ok := c.freqTable[tail][head]; ok {
+			t.Fatalf("freqTable[%q] contains %q: documents were linked across goroutines", tail, head)
+		}
+	}
+}
+
+func mustWrite(t *testing.T, w *ChainWriter, s string) {
+	t.Helper()
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatalf("Write(%q) returned error: %v", s, err)
+	}
+}
+
+func TestWriteBuildsFreqTable(t *testing.T) {
+	c := NewChain(1)
+	// Trailing space flushes the final word; without it the Tokenizer
+	// holds "b" back as a possibly-incomplete trailing token.
+	mustWrite(t, c.Writer(), "a b a c a b ")
+	if got := c.freqTable["a"]["b"]; got != 2 {
+		t.Fatalf(`freqTable["a"]["b"] = %d, want 2`, got)
+	}
+	if got := c.freqTable["a"]["c"]; got != 1 {
+		t.Fatalf(`freqTable["a"]["c"] = %d, want 1`, got)
+	}
+}
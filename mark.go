@@ -3,308 +3,201 @@
 // license that can be found in the LICENSE file.
 
 /*
-Generating random text: a Markov chain algorithm
+Command mark builds and queries Markov chains for generating random text.
 
-Based on the program presented in the "Design and Implementation" chapter
-of The Practice of Programming (Kernighan and Pike, Addison-Wesley 1999).
-See also Computer Recreations, Scientific American 260, 122 - 125 (1989).
+	mark read <prefixLen> <outputFile> <inputFile...>
+	mark generate <freqFile> <n> [seed]
+	mark talk <freqFile> <n> <seed words...>
+	mark server <prefixLen> <addr>
 
-A Markov chain algorithm generates text by creating a statistical model of
-potential textual suffixes for a given prefix. Consider this text:
+The read, generate and talk subcommands accept an optional
+--format=text|gob flag to pick the frequency-table encoding; generate and
+talk auto-detect the format from the input file when --format is omitted.
+The server subcommand ignores --format.
 
-	I am not a number! I am a free man!
-
-Our Markov chain algorithm would arrange this text into this set of prefixes
-and suffixes, or "chain": (This table assumes a prefix length of two words.)
-
-	Prefix       Suffix
-
-	"" ""        I
-	"" I         am
-	I am         a
-	I am         not
-	a free       man!
-	am a         free
-	am not       a
-	a number!    I
-	number! I    am
-	not a        number!
-
-To generate text using this table we select an initial prefix ("I am", for
-example), choose one of the suffixes associated with that prefix at random
-with probability determined by the input statistics ("a"),
-and then create a new prefix by removing the first word from the prefix
-and appending the suffix (making the new prefix is "am a"). Repeat this process
-until we can't find any suffixes for the current prefix or we exceed the word
-limit. (The word limit is necessary as the chain table may contain cycles.)
-
-Our version of this program reads text from standard input, parsing it into a
-Markov chain, and writes generated text to standard output.
-The prefix and output lengths can be specified using the -prefix and -words
-flags on the command-line.
+generate and talk also accept --sentence, which treats n as a maximum
+word count and stops at the first sentence-ending punctuation instead
+of running for exactly n words — useful for generating headlines.
 */
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"math/rand"
+	"net/http"
 	"os"
-	"sort"
 	"strconv"
 	"strings"
-)
-
-// Prefix is a Markov chain prefix of one or more words.
-type Prefix []string
-
-// String returns the Prefix as a string (for use as a map key).
-func (p Prefix) String() string {
-	return strings.Join(p, " ")
-}
-
-// Shift removes the first word from the Prefix and appends the given word.
-func (p Prefix) Shift(word string) {
-	copy(p, p[1:])
-	p[len(p)-1] = word
-}
-
-// Chain contains a map ("chain") of prefixes to a list of suffixes.
-// A prefix is a string of prefixLen words joined with spaces.
-// A suffix is a single word. A prefix can have multiple suffixes.
-type Chain struct {
-	chain     map[string][]string
-	prefixLen int
-	freqTable map[string]map[string]int
-}
-
-// NewChain returns a new Chain with prefixes of prefixLen words.
-func NewChain(prefixLen int) *Chain {
-	return &Chain{make(map[string][]string), prefixLen, make(map[string]map[string]int)}
-}
-
-// Build reads text from the provided Reader and
-// parses it into prefixes and suffixes that are stored in Chain.
-func (c *Chain) Build(r io.Reader) {
-	br := bufio.NewReader(r)
-	startPrefix := make([]string, c.prefixLen)
-	for i := range startPrefix {
-		startPrefix[i] = "\"\""
-	}
-	var p Prefix = startPrefix
-
-	// p := make(Prefix, c.prefixLen)
-	for {
-		var s string
-		if _, err := fmt.Fscan(br, &s); err != nil {
-			break
-		}
-		key := p.String()
-
-		if val, ok := c.freqTable[key]; ok { // if prefix is in table
-			val[s]++
-		} else { // if prefix is not in table
-			c.freqTable[key] = map[string]int{s: 1}
-		}
-
-		c.chain[key] = append(c.chain[key], s)
-		p.Shift(s)
-	}
-}
-
-// FileToFreqTable reads a file and adds content to a frequency table
-func (c *Chain) FileToFreqTable(filename string) {
-	openFile, err := os.Open(filename)
-	if err != nil {
-		panic("Could not open input file.")
-	}
-	scanner := bufio.NewScanner(openFile)
-	scanner.Split(bufio.ScanWords)
-
-	startPrefix := make([]string, c.prefixLen)
-	for i := range startPrefix {
-		startPrefix[i] = "\"\""
-	}
-	var p Prefix = startPrefix
 
-	for scanner.Scan() {
-		s := scanner.Text()
-		key := p.String()
+	"github.com/psimps21/Mark-V-Shaney/chain"
+	"github.com/psimps21/Mark-V-Shaney/server"
+)
 
-		if val, ok := c.freqTable[key]; ok { // if prefix is in table
-			val[s]++
-		} else { // if prefix is not in table
-			c.freqTable[key] = map[string]int{s: 1}
+// parseFormatFlag pulls a "--format=text" or "--format=gob" flag out of
+// args, wherever it appears, and returns the chosen format plus the
+// remaining positional arguments. An empty format means "not given".
+func parseFormatFlag(args []string) (format string, rest []string) {
+	const prefix = "--format="
+	for _, a := range args {
+		if strings.HasPrefix(a, prefix) {
+			format = a[len(prefix):]
+			continue
 		}
-
-		c.chain[key] = append(c.chain[key], s)
-		p.Shift(s)
+		rest = append(rest, a)
 	}
+	return format, rest
 }
 
-// PrintFreqTable prints a frequency table
-func (c *Chain) PrintFreqTable() {
-	for k, v := range c.freqTable {
-		var sufCount []string
-		for k2, v2 := range v {
-			sufCount = append(sufCount, k2, strconv.Itoa(v2))
+// parseSentenceFlag pulls a "--sentence" flag out of args, wherever it
+// appears, and returns whether it was present plus the remaining
+// positional arguments.
+func parseSentenceFlag(args []string) (sentence bool, rest []string) {
+	for _, a := range args {
+		if a == "--sentence" {
+			sentence = true
+			continue
 		}
-		fmt.Println(k + " " + strings.Join(sufCount, " "))
-	}
-}
-
-//PrintChain prints a chain
-func (c *Chain) PrintChain() {
-	for key := range c.chain {
-		fmt.Println(key, strings.Join(c.chain[key], " "))
+		rest = append(rest, a)
 	}
+	return sentence, rest
 }
 
-//ChainToFile writes the contents of a chain to a file
-func (c *Chain) FreqTableToFile(filepath string) {
-	// Open file
-	openFile, err := os.Create(filepath)
+// loadChain opens filename and parses it as a Chain using format, or by
+// auto-detecting the format from its leading bytes when format is "".
+func loadChain(filename, format string) (*chain.Chain, error) {
+	openFile, err := os.Open(filename)
 	if err != nil {
-		panic("Could not create file from given file path")
+		return nil, err
 	}
 	defer openFile.Close()
+	return chain.LoadFormat(openFile, format)
+}
 
-	writer := bufio.NewWriter(openFile)
-	fmt.Fprintln(writer, strconv.Itoa(c.prefixLen))
+func main() {
+	format, args := parseFormatFlag(os.Args[1:])
+	sentence, args := parseSentenceFlag(args)
+	argv := append([]string{os.Args[0]}, args...)
 
-	// Sort frequence table
-	var keys []string
-	for k := range c.freqTable {
-		keys = append(keys, k)
+	if len(argv) < 4 {
+		panic("Command does not have enough arguments")
 	}
-	sort.Strings(keys)
 
-	// Write each line of the output file
-	for _, key := range keys {
-		var sufCount []string
-		for k2, v2 := range c.freqTable[key] {
-			sufCount = append(sufCount, k2, strconv.Itoa(v2))
+	runType := argv[1]
+	if runType == "generate" {
+		freqFile := argv[2]
+		n, err := strconv.Atoi(argv[3])
+		if err != nil {
+			panic("Could not convert integer to string")
+		}
+		if n < 0 {
+			panic("Number of words must be positive")
 		}
-		fmt.Fprintln(writer, key+" "+strings.Join(sufCount, " "))
-	}
-	writer.Flush()
-}
 
-// FreqTableFromFile create a frequency table from an freqTable file
-func FreqTableFromFreqFile(freqFile string) Chain {
-	openFile, err := os.Open(freqFile)
-	if err != nil {
-		panic("Could not open frequency table file.")
-	}
-	scanner := bufio.NewScanner(openFile)
+		c, err := loadChain(freqFile, format)
+		if err != nil {
+			panic("Could not load frequency table: " + err.Error())
+		}
 
-	c := NewChain(0)
-	for scanner.Scan() {
-		items := strings.Split(scanner.Text(), " ")
-		if len(items) > 1 { // if not first line of file and line is not empty
-			// set prefix for the line
-			var p Prefix
-			for i := 0; i < c.prefixLen; i++ {
-				p = append(p, items[i])
+		var text string
+		switch {
+		case sentence:
+			// --sentence treats n as a max word count and stops at the
+			// first sentence boundary instead of running for exactly n
+			// words.
+			text = c.GenerateSentence(n)
+		case len(argv) > 4:
+			// An optional seed makes the generated text reproducible.
+			seed, err := strconv.ParseInt(argv[4], 10, 64)
+			if err != nil {
+				panic("Could not convert seed to integer")
 			}
-			key := p.String()
-			c.freqTable[key] = make(map[string]int)
+			text = c.GenerateWithSeed(n, seed)
+		default:
+			text = c.Generate(n)
+		}
+		fmt.Println(text) // Write text to standard output.
 
-			// update prefix map with suffix frequencies
-			for i := c.prefixLen; i < len(items); i = i + 2 {
-				sfxFreq, err := strconv.Atoi(items[i+1])
-				if err != nil {
-					panic("Could not convert string to integer")
-				}
-				c.freqTable[key][items[i]] = sfxFreq
+	} else if runType == "read" {
+		prefixLen, err := strconv.Atoi(argv[2])
+		if err != nil {
+			panic("Could not convert integer to string")
+		}
+		if prefixLen < 1 {
+			panic("Prefix length must be greater than 0")
+		}
+		outputFile := argv[3]
+
+		c := chain.NewChain(prefixLen)
+		// read all input files, each as its own document via a fresh
+		// ChainWriter, so the end of one file isn't linked to the start
+		// of the next
+		for i := 4; i < len(argv); i++ {
+			inputFile, err := os.Open(argv[i])
+			if err != nil {
+				panic("Could not open input file.")
 			}
-		} else if len(items) == 1 { // if first line or empty line
-			if len(items[0]) > 0 { // if ifrst line
-				// Set the prefix length for the chain
-				prefixLen, err := strconv.Atoi(items[0])
-				if err != nil {
-					panic("Could not convert string to integer")
-				}
-				c.prefixLen = prefixLen
+			if _, err := io.Copy(c.Writer(), inputFile); err != nil {
+				panic("Could not read input file.")
 			}
+			inputFile.Close()
 		}
-	}
-	return *c
-}
 
-// ChainFromFreqTable generates a chain from a frequency table
-func (c *Chain) ChainFromFreqTable() {
-	for k, v := range c.freqTable { // for prefix in frequency table
-		var sfxs []string
-		for k2, v2 := range v { // for suffix in suffix map
-			// append the key to for chain based on frequency
-			for i := 0; i < v2; i++ {
-				sfxs = append(sfxs, k2)
+		if format == "gob" {
+			outFile, err := os.Create(outputFile)
+			if err != nil {
+				panic("Could not create file from given file path")
 			}
+			defer outFile.Close()
+			if err := c.Save(outFile); err != nil {
+				panic("Could not save gob-encoded chain: " + err.Error())
+			}
+		} else {
+			// Save frequency table to output file
+			c.FreqTableToFile(outputFile)
 		}
-		c.chain[k] = sfxs
-	}
-}
-
-// Generate returns a string of at most n words generated from Chain.
-func (c *Chain) Generate(n int) string {
-	startPrefix := make([]string, c.prefixLen)
-	for i := range startPrefix {
-		startPrefix[i] = "\"\""
-	}
-	var p Prefix = startPrefix
-	var words []string
-	for i := 0; i < n; i++ {
-		choices := c.chain[p.String()]
-		if len(choices) == 0 {
-			break
-		}
-		next := choices[rand.Intn(len(choices))]
-		words = append(words, next)
-		p.Shift(next)
-	}
-	return strings.Join(words, " ")
-}
-
-func main() {
-	if len(os.Args) < 4 {
-		panic("Command does not have enough arguments")
-	}
 
-	runType := os.Args[1]
-	if runType == "generate" {
-		freqFile := os.Args[2]
-		n, err := strconv.Atoi(os.Args[3])
+	} else if runType == "talk" {
+		freqFile := argv[2]
+		n, err := strconv.Atoi(argv[3])
 		if err != nil {
 			panic("Could not convert integer to string")
 		}
 		if n < 0 {
 			panic("Number of words must be positive")
 		}
+		if len(argv) < 5 {
+			panic("talk requires at least one seed word")
+		}
+		seedWords := argv[4:]
 
-		c := FreqTableFromFreqFile(freqFile)
-		c.ChainFromFreqTable()
-		text := c.Generate(n) // Generate text.
-		fmt.Println(text)     // Write text to standard output.
+		c, err := loadChain(freqFile, format)
+		if err != nil {
+			panic("Could not load frequency table: " + err.Error())
+		}
 
-	} else if runType == "read" {
-		prefixLen, err := strconv.Atoi(os.Args[2])
+		var text string
+		if sentence {
+			text = c.GenerateSentenceFrom(seedWords, n)
+		} else {
+			text = c.GenerateFrom(seedWords, n)
+		}
+		fmt.Println(text) // Write text to standard output.
+
+	} else if runType == "server" {
+		prefixLen, err := strconv.Atoi(argv[2])
 		if err != nil {
 			panic("Could not convert integer to string")
 		}
 		if prefixLen < 1 {
 			panic("Prefix length must be greater than 0")
 		}
-		outputFile := os.Args[3]
+		addr := argv[3]
 
-		c := NewChain(prefixLen)
-		// read all input files
-		for i := 4; i < len(os.Args); i++ {
-			c.FileToFreqTable(os.Args[i])
+		c := chain.NewChain(prefixLen)
+		srv := server.NewServer(c)
+		if err := http.ListenAndServe(addr, srv.Handler()); err != nil {
+			panic("Server failed: " + err.Error())
 		}
-		// Save frequency table to output file
-		c.FreqTableToFile(outputFile)
 
 	} else {
 		panic("Invalid word in program command")
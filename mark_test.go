@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// markBinary is built once in TestMain and shared by the subprocess
+// tests below, which drive the talk subcommand the way a user would
+// from a shell rather than calling main()'s internals directly.
+var markBinary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mark-test")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	markBinary = filepath.Join(dir, "mark")
+	build := exec.Command("go", "build", "-o", markBinary, ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		panic("go build failed: " + err.Error() + "\n" + string(out))
+	}
+
+	os.Exit(m.Run())
+}
+
+// trainedFreqFile writes a small input document, runs "mark read"
+// against it, and returns the path to the resulting frequency file.
+func trainedFreqFile(t *testing.T, dir string) string {
+	t.Helper()
+
+	inputFile := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(inputFile, []byte("the dog barks the dog runs "), 0o644); err != nil {
+		t.Fatalf("WriteFile(input) failed: %v", err)
+	}
+
+	freqFile := filepath.Join(dir, "freq.txt")
+	cmd := exec.Command(markBinary, "read", "1", freqFile, inputFile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("mark read failed: %v\n%s", err, out)
+	}
+	return freqFile
+}
+
+func TestTalkRequiresSeedWord(t *testing.T) {
+	freqFile := trainedFreqFile(t, t.TempDir())
+
+	cmd := exec.Command(markBinary, "talk", freqFile, "10")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("mark talk with no seed words exited 0, want a non-zero exit; output: %s", out)
+	}
+	if !strings.Contains(string(out), "seed word") {
+		t.Fatalf("mark talk with no seed words output %q, want it to mention the missing seed word", out)
+	}
+}
+
+func TestTalkProducesOutput(t *testing.T) {
+	freqFile := trainedFreqFile(t, t.TempDir())
+
+	cmd := exec.Command(markBinary, "talk", freqFile, "10", "the")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("mark talk failed: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		t.Fatalf("mark talk produced no output")
+	}
+}
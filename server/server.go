@@ -0,0 +1,153 @@
+// Package server exposes a chain.Chain for training and text generation
+// over HTTP, so a Markov chain can back a long-running process — a chat
+// bot, a website, or a sidecar — instead of only a one-shot CLI run.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/psimps21/Mark-V-Shaney/chain"
+)
+
+// Server wraps a *chain.Chain with HTTP handlers for training and
+// generation. Concurrent requests don't corrupt each other's training
+// data: each /train request gets its own chain.ChainWriter, so two
+// requests racing each other train as two independent documents rather
+// than interleaving through a shared prefix cursor.
+type Server struct {
+	chain *chain.Chain
+}
+
+// NewServer returns a Server backed by c.
+func NewServer(c *chain.Chain) *Server {
+	return &Server{chain: c}
+}
+
+// Handler returns the Server's http.Handler, so callers can mount it
+// under an existing mux instead of Server always owning the whole
+// process's routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/train", s.handleTrain)
+	mux.HandleFunc("/generate", s.handleGenerate)
+	mux.HandleFunc("/dump", s.handleDump)
+	mux.HandleFunc("/load", s.handleLoad)
+	return mux
+}
+
+// handleTrain accepts a text/plain body and streams it into the Chain
+// via io.Copy, the same path the CLI uses to read training files in, so
+// a client can train the Chain over the wire without buffering the
+// body. Each request trains through its own chain.ChainWriter, so the
+// body is treated as one independent document: concurrent /train
+// requests never link words across each other's bodies, regardless of
+// how their reads happen to interleave.
+func (s *Server) handleTrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	if _, err := io.Copy(s.chain.Writer(), r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// generateResponse is the JSON body handleGenerate writes.
+type generateResponse struct {
+	Text string `json:"text"`
+}
+
+// handleGenerate returns JSON {"text": "..."} generated from the
+// Chain. The "n" query parameter sets the word count (default 50); an
+// optional "seed" query parameter steers generation via
+// chain.Chain.GenerateFrom. If "sentence" is set to a true-ish value
+// ("1", "t", "true", ...; see strconv.ParseBool), n is instead a
+// maximum word count and generation stops at the first
+// sentence-ending punctuation, for a "headline" style result.
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n := 50
+	if v := r.URL.Query().Get("n"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			http.Error(w, "n must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+
+	sentence := false
+	if v := r.URL.Query().Get("sentence"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "sentence must be a boolean", http.StatusBadRequest)
+			return
+		}
+		sentence = parsed
+	}
+
+	seed := r.URL.Query().Get("seed")
+	var text string
+	switch {
+	case sentence && seed != "":
+		text = s.chain.GenerateSentenceFrom(strings.Fields(seed), n)
+	case sentence:
+		text = s.chain.GenerateSentence(n)
+	case seed != "":
+		text = s.chain.GenerateFrom(strings.Fields(seed), n)
+	default:
+		text = s.chain.Generate(n)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(generateResponse{Text: text})
+}
+
+// handleDump writes a gob-encoded snapshot of the Chain, as produced by
+// chain.Chain.Save, so it can be restored later via /load. The snapshot
+// is built in memory first so a Save error can still be reported with a
+// proper status code instead of after a body has already been sent.
+func (s *Server) handleDump(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := s.chain.Save(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(buf.Bytes())
+}
+
+// handleLoad restores the Chain in place from a gob-encoded snapshot in
+// the request body, as produced by /dump.
+func (s *Server) handleLoad(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := s.chain.Load(r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}